@@ -0,0 +1,229 @@
+package httpc
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned by CircuitBreakerLayer.RoundTrip while a
+// breaker is open or has exhausted its half-open probes.
+var ErrCircuitOpen = errors.New("httpc: circuit breaker open")
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+// CircuitBreakerConfig configures the Layer installed by WithCircuitBreaker.
+type CircuitBreakerConfig struct {
+	// Window is the rolling duration over which FailureRatio is evaluated.
+	Window time.Duration
+	// MinRequests is the minimum number of requests observed in Window
+	// before the breaker can trip.
+	MinRequests uint32
+	// FailureRatio, once MinRequests is reached, trips the breaker open.
+	FailureRatio float64
+	// OpenTimeout is how long the breaker stays open before allowing
+	// half-open probes.
+	OpenTimeout time.Duration
+	// HalfOpenMaxProbes is how many requests are admitted while half-open
+	// before the breaker closes (all succeed) or re-opens (any fails).
+	HalfOpenMaxProbes uint32
+
+	// IsFailure classifies an outcome as a breaker failure. Defaults to
+	// treating network errors and 5xx responses as failures.
+	IsFailure func(resp *http.Response, err error) bool
+
+	// KeyFn partitions requests across independent breakers, e.g. per host
+	// or route. Defaults to a single breaker shared by all requests.
+	KeyFn func(req *http.Request) string
+}
+
+func defaultBreakerIsFailure(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode >= 500
+}
+
+// WithCircuitBreaker installs a Layer implementing a three-state
+// (closed/open/half-open) circuit breaker, keyed by cfg.KeyFn.
+func WithCircuitBreaker(cfg CircuitBreakerConfig) Option {
+	if cfg.IsFailure == nil {
+		cfg.IsFailure = defaultBreakerIsFailure
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = 10 * time.Second
+	}
+	if cfg.MinRequests == 0 {
+		cfg.MinRequests = 1
+	}
+	if cfg.FailureRatio <= 0 {
+		cfg.FailureRatio = 0.5
+	}
+	if cfg.OpenTimeout <= 0 {
+		cfg.OpenTimeout = 30 * time.Second
+	}
+	if cfg.HalfOpenMaxProbes == 0 {
+		cfg.HalfOpenMaxProbes = 1
+	}
+
+	registry := &breakerRegistry{breakers: map[string]*circuitBreaker{}}
+
+	return WithLayer(func(base http.RoundTripper) http.RoundTripper {
+		return &CircuitBreakerLayer{
+			base:     base,
+			cfg:      cfg,
+			registry: registry,
+		}
+	})
+}
+
+// breakerRegistry holds the keyed breakers for a single WithCircuitBreaker
+// installation. It's constructed once in WithCircuitBreaker, not in the
+// Layer closure, so breaker state survives a client's Layer chain being
+// rebuilt by AddOptions/Extend.
+type breakerRegistry struct {
+	mu       sync.Mutex
+	breakers map[string]*circuitBreaker
+}
+
+func (r *breakerRegistry) breakerFor(cfg CircuitBreakerConfig, key string) *circuitBreaker {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b, ok := r.breakers[key]
+	if !ok {
+		b = &circuitBreaker{cfg: cfg}
+		r.breakers[key] = b
+	}
+
+	return b
+}
+
+// CircuitBreakerLayer is the http.RoundTripper backing WithCircuitBreaker.
+type CircuitBreakerLayer struct {
+	base     http.RoundTripper
+	cfg      CircuitBreakerConfig
+	registry *breakerRegistry
+}
+
+func (l *CircuitBreakerLayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := ""
+	if l.cfg.KeyFn != nil {
+		key = l.cfg.KeyFn(req)
+	}
+
+	b := l.registry.breakerFor(l.cfg, key)
+	admitted, gen := b.allow()
+	if !admitted {
+		return nil, ErrCircuitOpen
+	}
+
+	resp, err := l.base.RoundTrip(req)
+	b.record(gen, l.cfg.IsFailure(resp, err))
+
+	return resp, err
+}
+
+// circuitBreaker is a single keyed breaker's state machine.
+type circuitBreaker struct {
+	mu  sync.Mutex
+	cfg CircuitBreakerConfig
+
+	state breakerState
+	// generation is bumped on every state transition (trip, open->half-open,
+	// half-open->closed). allow() stamps the generation it admitted a
+	// request under; record() compares against the current generation and
+	// drops completions from an era that has since ended, e.g. a half-open
+	// probe slow enough to outlive a full open->half-open->open cycle.
+	generation uint64
+
+	windowStart     time.Time
+	total, failures uint32
+
+	openedAt                       time.Time
+	halfOpenProbes, halfOpenPasses uint32
+}
+
+func (b *circuitBreaker) allow() (admitted bool, generation uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == breakerOpen {
+		if time.Since(b.openedAt) < b.cfg.OpenTimeout {
+			return false, 0
+		}
+		b.state = breakerHalfOpen
+		b.halfOpenProbes, b.halfOpenPasses = 0, 0
+		b.generation++
+	}
+
+	if b.state == breakerHalfOpen {
+		if b.halfOpenProbes >= b.cfg.HalfOpenMaxProbes {
+			return false, 0
+		}
+		b.halfOpenProbes++
+	}
+
+	return true, b.generation
+}
+
+func (b *circuitBreaker) record(generation uint64, failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if generation != b.generation {
+		// This completion was admitted under an era that has since ended
+		// (the breaker tripped, or cycled open->half-open->open again
+		// before this one finished); it must not feed the current era's
+		// window or state transitions.
+		return
+	}
+
+	if b.state == breakerHalfOpen {
+		if failed {
+			b.trip()
+			return
+		}
+		b.halfOpenPasses++
+		if b.halfOpenPasses >= b.cfg.HalfOpenMaxProbes {
+			b.reset(breakerClosed)
+		}
+		return
+	}
+
+	now := time.Now()
+	if b.windowStart.IsZero() || now.Sub(b.windowStart) > b.cfg.Window {
+		b.windowStart = now
+		b.total, b.failures = 0, 0
+	}
+
+	b.total++
+	if failed {
+		b.failures++
+	}
+
+	if b.total >= b.cfg.MinRequests && float64(b.failures)/float64(b.total) >= b.cfg.FailureRatio {
+		b.trip()
+	}
+}
+
+func (b *circuitBreaker) trip() {
+	b.state = breakerOpen
+	b.openedAt = time.Now()
+	b.total, b.failures = 0, 0
+	b.generation++
+}
+
+func (b *circuitBreaker) reset(state breakerState) {
+	b.state = state
+	b.total, b.failures = 0, 0
+	b.halfOpenProbes, b.halfOpenPasses = 0, 0
+	b.generation++
+}
@@ -0,0 +1,212 @@
+package httpc
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithCircuitBreaker(t *testing.T) {
+	t.Run("opens after the failure ratio is reached", func(t *testing.T) {
+		s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+			rw.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer s.Close()
+		client := New(WithCircuitBreaker(CircuitBreakerConfig{
+			Window:            time.Minute,
+			MinRequests:       2,
+			FailureRatio:      0.5,
+			OpenTimeout:       time.Hour,
+			HalfOpenMaxProbes: 1,
+		}))
+		req, err := http.NewRequest(http.MethodGet, s.URL, nil)
+		require.NoError(t, err)
+
+		_, err = client.DoReq(req)
+		assert.Error(t, err)
+		_, err = client.DoReq(req)
+		assert.Error(t, err)
+
+		_, err = client.DoReq(req)
+		assert.ErrorIs(t, err, ErrCircuitOpen)
+	})
+
+	t.Run("half-open probe closes the breaker on success", func(t *testing.T) {
+		fail := true
+		s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+			if fail {
+				rw.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			rw.WriteHeader(http.StatusOK)
+		}))
+		defer s.Close()
+		client := New(WithCircuitBreaker(CircuitBreakerConfig{
+			Window:            time.Minute,
+			MinRequests:       1,
+			FailureRatio:      0.5,
+			OpenTimeout:       10 * time.Millisecond,
+			HalfOpenMaxProbes: 1,
+		}))
+		req, err := http.NewRequest(http.MethodGet, s.URL, nil)
+		require.NoError(t, err)
+
+		_, err = client.DoReq(req)
+		assert.Error(t, err)
+		_, err = client.DoReq(req)
+		assert.ErrorIs(t, err, ErrCircuitOpen)
+
+		time.Sleep(20 * time.Millisecond)
+		fail = false
+
+		resp, err := client.DoReq(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp, err = client.DoReq(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("4xx responses do not trip the breaker", func(t *testing.T) {
+		s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+			rw.WriteHeader(http.StatusBadRequest)
+		}))
+		defer s.Close()
+		client := New(WithCircuitBreaker(CircuitBreakerConfig{
+			Window:            time.Minute,
+			MinRequests:       1,
+			FailureRatio:      0.1,
+			OpenTimeout:       time.Hour,
+			HalfOpenMaxProbes: 1,
+		}))
+		req, err := http.NewRequest(http.MethodGet, s.URL, nil)
+		require.NoError(t, err)
+
+		_, err = client.DoReq(req)
+		assert.Error(t, err)
+		assert.False(t, errors.Is(err, ErrCircuitOpen))
+
+		_, err = client.DoReq(req)
+		assert.False(t, errors.Is(err, ErrCircuitOpen))
+	})
+
+	t.Run("recovers from open even when HalfOpenMaxProbes is left unset", func(t *testing.T) {
+		fail := true
+		s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+			if fail {
+				rw.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			rw.WriteHeader(http.StatusOK)
+		}))
+		defer s.Close()
+		client := New(WithCircuitBreaker(CircuitBreakerConfig{
+			Window:       time.Minute,
+			MinRequests:  1,
+			FailureRatio: 0.5,
+			OpenTimeout:  10 * time.Millisecond,
+		}))
+		req, err := http.NewRequest(http.MethodGet, s.URL, nil)
+		require.NoError(t, err)
+
+		_, err = client.DoReq(req)
+		assert.Error(t, err)
+		_, err = client.DoReq(req)
+		assert.ErrorIs(t, err, ErrCircuitOpen)
+
+		time.Sleep(20 * time.Millisecond)
+		fail = false
+
+		resp, err := client.DoReq(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("a late completion from a stale half-open era does not re-extend the open window", func(t *testing.T) {
+		b := &circuitBreaker{cfg: CircuitBreakerConfig{
+			Window:            time.Minute,
+			MinRequests:       1,
+			FailureRatio:      0.5,
+			OpenTimeout:       time.Hour,
+			HalfOpenMaxProbes: 2,
+		}}
+		b.state = breakerHalfOpen
+
+		admitted1, gen1 := b.allow()
+		admitted2, gen2 := b.allow()
+		require.True(t, admitted1)
+		require.True(t, admitted2)
+
+		b.record(gen1, true)
+		require.Equal(t, breakerOpen, b.state)
+		openedAt := b.openedAt
+
+		b.record(gen2, true)
+		assert.Equal(t, breakerOpen, b.state)
+		assert.Equal(t, openedAt, b.openedAt, "a stale half-open completion must not push openedAt out")
+	})
+
+	t.Run("a probe admitted in an earlier half-open era is ignored even after a full reopen cycle", func(t *testing.T) {
+		b := &circuitBreaker{cfg: CircuitBreakerConfig{
+			Window:            time.Minute,
+			MinRequests:       1,
+			FailureRatio:      0.5,
+			OpenTimeout:       0,
+			HalfOpenMaxProbes: 1,
+		}}
+		b.state = breakerHalfOpen
+
+		_, staleGen := b.allow()
+
+		// The probe from staleGen fails, tripping the breaker open again...
+		b.record(staleGen, true)
+		require.Equal(t, breakerOpen, b.state)
+
+		// ...which, since OpenTimeout is 0, immediately cycles back to
+		// half-open on the next admission, starting a new era.
+		admitted, freshGen := b.allow()
+		require.True(t, admitted)
+		require.NotEqual(t, staleGen, freshGen)
+
+		// A very late completion from the original (now stale) probe arrives
+		// and must not affect the fresh era.
+		b.record(staleGen, false)
+		assert.Equal(t, breakerHalfOpen, b.state)
+		assert.Equal(t, uint32(0), b.halfOpenPasses, "stale completion must not be counted toward the fresh era")
+
+		b.record(freshGen, false)
+		assert.Equal(t, breakerClosed, b.state)
+	})
+
+	t.Run("breaker state survives AddOptions rebuilding the client's Layer chain", func(t *testing.T) {
+		s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+			rw.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer s.Close()
+		client := New(WithCircuitBreaker(CircuitBreakerConfig{
+			Window:            time.Minute,
+			MinRequests:       1,
+			FailureRatio:      0.5,
+			OpenTimeout:       time.Hour,
+			HalfOpenMaxProbes: 1,
+		}))
+		req, err := http.NewRequest(http.MethodGet, s.URL, nil)
+		require.NoError(t, err)
+
+		_, err = client.DoReq(req)
+		assert.Error(t, err)
+		_, err = client.DoReq(req)
+		assert.ErrorIs(t, err, ErrCircuitOpen)
+
+		client.AddOptions(WithHeaders(http.Header{"X-Unrelated": []string{"1"}}))
+
+		_, err = client.DoReq(req)
+		assert.ErrorIs(t, err, ErrCircuitOpen, "AddOptions must not discard breaker state")
+	})
+}
@@ -27,6 +27,7 @@ type (
 		Jar           http.CookieJar
 		Timeout       time.Duration
 		JsonUnmarshal JsonUnmarshaler
+		JsonMarshal   JsonMarshaler
 
 		layers       []Layer
 		errorHandler ErrorHandler
@@ -34,6 +35,8 @@ type (
 
 	JsonUnmarshaler func(data []byte, obj any) error
 
+	JsonMarshaler func(obj any) ([]byte, error)
+
 	Layer func(base http.RoundTripper) http.RoundTripper
 
 	ErrorHandler func(c *Client, resp *http.Response, body []byte) error
@@ -143,6 +146,7 @@ func newDefaultClient() *Client {
 			Timeout:       DefaultTimeout,
 			Transport:     DefaultTransport,
 			JsonUnmarshal: json.Unmarshal,
+			JsonMarshal:   json.Marshal,
 			errorHandler:  bytesErrorHandler,
 		},
 	}
@@ -0,0 +1,123 @@
+package httpc
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// WithDecompression installs a DecompressLayer that transparently decodes
+// response bodies whose Content-Encoding matches one of the given algos
+// ("gzip", "deflate", "br", "zstd") and advertises the same algos via an
+// Accept-Encoding header on outgoing requests. DoReq, JSON and Stream all
+// see the decoded bytes, since decoding happens in the RoundTripper chain
+// before any of them ever touch resp.Body.
+func WithDecompression(algos ...string) Option {
+	return WithLayer(NewDecompressLayer(algos...))
+}
+
+// NewDecompressLayer builds the Layer used by WithDecompression. Exposed
+// separately so it can be composed manually with WithLayer, e.g. to control
+// its position relative to other layers.
+func NewDecompressLayer(algos ...string) Layer {
+	accepted := make([]string, 0, len(algos))
+	algoSet := make(map[string]bool, len(algos))
+	for _, a := range algos {
+		a = strings.ToLower(strings.TrimSpace(a))
+		if a == "" || algoSet[a] {
+			continue
+		}
+		accepted = append(accepted, a)
+		algoSet[a] = true
+	}
+
+	return func(base http.RoundTripper) http.RoundTripper {
+		return &DecompressLayer{
+			base:           base,
+			algos:          algoSet,
+			acceptEncoding: strings.Join(accepted, ", "),
+		}
+	}
+}
+
+// DecompressLayer is the http.RoundTripper backing WithDecompression.
+type DecompressLayer struct {
+	base           http.RoundTripper
+	algos          map[string]bool
+	acceptEncoding string
+}
+
+func (d *DecompressLayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if d.acceptEncoding != "" && req.Header.Get("Accept-Encoding") == "" {
+		req.Header.Set("Accept-Encoding", d.acceptEncoding)
+	}
+
+	resp, err := d.base.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+
+	enc := strings.ToLower(resp.Header.Get("Content-Encoding"))
+	if enc == "" || !d.algos[enc] {
+		return resp, nil
+	}
+
+	body, err := decompressedBody(enc, resp.Body)
+	if err != nil {
+		_ = resp.Body.Close()
+		return nil, err
+	}
+
+	resp.Body = body
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+
+	return resp, nil
+}
+
+func decompressedBody(enc string, raw io.ReadCloser) (io.ReadCloser, error) {
+	switch enc {
+	case "gzip":
+		r, err := gzip.NewReader(raw)
+		if err != nil {
+			return nil, err
+		}
+		return &decodedBody{Reader: r, raw: raw, closeDecoder: r.Close}, nil
+	case "deflate":
+		r := flate.NewReader(raw)
+		return &decodedBody{Reader: r, raw: raw, closeDecoder: r.Close}, nil
+	case "br":
+		r := brotli.NewReader(raw)
+		return &decodedBody{Reader: r, raw: raw}, nil
+	case "zstd":
+		r, err := zstd.NewReader(raw)
+		if err != nil {
+			return nil, err
+		}
+		return &decodedBody{Reader: r, raw: raw, closeDecoder: func() error { r.Close(); return nil }}, nil
+	default:
+		return nil, fmt.Errorf("httpc: unsupported content-encoding %q", enc)
+	}
+}
+
+// decodedBody wraps a decompressing io.Reader together with the raw
+// response body it reads from, so closing it releases both.
+type decodedBody struct {
+	io.Reader
+	raw          io.ReadCloser
+	closeDecoder func() error
+}
+
+func (d *decodedBody) Close() error {
+	if d.closeDecoder != nil {
+		_ = d.closeDecoder()
+	}
+	return d.raw.Close()
+}
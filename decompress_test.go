@@ -0,0 +1,175 @@
+package httpc
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type closeTrackingBody struct {
+	io.Reader
+	closed bool
+}
+
+func (b *closeTrackingBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+type fakeRoundTripper struct {
+	resp *http.Response
+}
+
+func (f *fakeRoundTripper) RoundTrip(*http.Request) (*http.Response, error) {
+	return f.resp, nil
+}
+
+func TestWithDecompression(t *testing.T) {
+	t.Run("decodes gzip body", func(t *testing.T) {
+		var gotAcceptEncoding string
+		s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			gotAcceptEncoding = req.Header.Get("Accept-Encoding")
+			rw.Header().Set("Content-Encoding", "gzip")
+			gz := gzip.NewWriter(rw)
+			_, err := gz.Write([]byte("hello world"))
+			require.NoError(t, err)
+			require.NoError(t, gz.Close())
+		}))
+		defer s.Close()
+		client := New(WithDecompression("gzip"))
+		req, err := http.NewRequest(http.MethodGet, s.URL, nil)
+		require.NoError(t, err)
+		buf := &bytes.Buffer{}
+
+		resp, err := client.DoReq(req, WithCopy(buf))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "gzip", gotAcceptEncoding)
+		assert.Equal(t, "hello world", buf.String())
+		assert.Empty(t, resp.Header.Get("Content-Encoding"))
+	})
+
+	t.Run("decodes deflate body", func(t *testing.T) {
+		s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+			rw.Header().Set("Content-Encoding", "deflate")
+			fw, err := flate.NewWriter(rw, flate.DefaultCompression)
+			require.NoError(t, err)
+			_, err = fw.Write([]byte("hello world"))
+			require.NoError(t, err)
+			require.NoError(t, fw.Close())
+		}))
+		defer s.Close()
+		client := New(WithDecompression("deflate"))
+		req, err := http.NewRequest(http.MethodGet, s.URL, nil)
+		require.NoError(t, err)
+		buf := &bytes.Buffer{}
+
+		resp, err := client.DoReq(req, WithCopy(buf))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "hello world", buf.String())
+		assert.Empty(t, resp.Header.Get("Content-Encoding"))
+	})
+
+	t.Run("decodes brotli body", func(t *testing.T) {
+		s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+			rw.Header().Set("Content-Encoding", "br")
+			bw := brotli.NewWriter(rw)
+			_, err := bw.Write([]byte("hello world"))
+			require.NoError(t, err)
+			require.NoError(t, bw.Close())
+		}))
+		defer s.Close()
+		client := New(WithDecompression("br"))
+		req, err := http.NewRequest(http.MethodGet, s.URL, nil)
+		require.NoError(t, err)
+		buf := &bytes.Buffer{}
+
+		resp, err := client.DoReq(req, WithCopy(buf))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "hello world", buf.String())
+		assert.Empty(t, resp.Header.Get("Content-Encoding"))
+	})
+
+	t.Run("decodes zstd body", func(t *testing.T) {
+		s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+			rw.Header().Set("Content-Encoding", "zstd")
+			zw, err := zstd.NewWriter(rw)
+			require.NoError(t, err)
+			_, err = zw.Write([]byte("hello world"))
+			require.NoError(t, err)
+			require.NoError(t, zw.Close())
+		}))
+		defer s.Close()
+		client := New(WithDecompression("zstd"))
+		req, err := http.NewRequest(http.MethodGet, s.URL, nil)
+		require.NoError(t, err)
+		buf := &bytes.Buffer{}
+
+		resp, err := client.DoReq(req, WithCopy(buf))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "hello world", buf.String())
+		assert.Empty(t, resp.Header.Get("Content-Encoding"))
+	})
+
+	t.Run("passes through unlisted encoding untouched", func(t *testing.T) {
+		s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+			rw.Header().Set("Content-Encoding", "br")
+			_, err := rw.Write([]byte("raw bytes"))
+			require.NoError(t, err)
+		}))
+		defer s.Close()
+		client := New(WithDecompression("gzip"))
+		req, err := http.NewRequest(http.MethodGet, s.URL, nil)
+		require.NoError(t, err)
+		buf := &bytes.Buffer{}
+
+		_, err = client.DoReq(req, WithCopy(buf))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "raw bytes", buf.String())
+	})
+
+	t.Run("no content-encoding header is a no-op", func(t *testing.T) {
+		s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+			_, err := rw.Write([]byte("plain"))
+			require.NoError(t, err)
+		}))
+		defer s.Close()
+		client := New(WithDecompression("gzip", "br", "zstd"))
+		req, err := http.NewRequest(http.MethodGet, s.URL, nil)
+		require.NoError(t, err)
+		buf := &bytes.Buffer{}
+
+		_, err = client.DoReq(req, WithCopy(buf))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "plain", buf.String())
+	})
+
+	t.Run("closes the raw body when decoding fails", func(t *testing.T) {
+		body := &closeTrackingBody{Reader: bytes.NewReader([]byte("not gzip"))}
+		resp := &http.Response{
+			Header: http.Header{"Content-Encoding": []string{"gzip"}},
+			Body:   body,
+		}
+		layer := NewDecompressLayer("gzip")(&fakeRoundTripper{resp: resp})
+
+		got, err := layer.RoundTrip(&http.Request{Header: http.Header{}})
+
+		assert.Error(t, err)
+		assert.Nil(t, got)
+		assert.True(t, body.closed)
+	})
+}
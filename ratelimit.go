@@ -0,0 +1,33 @@
+package httpc
+
+import (
+	"net/http"
+
+	"golang.org/x/time/rate"
+)
+
+// WithRateLimit installs a Layer that throttles outgoing requests to rps
+// requests per second, allowing bursts of up to burst requests, using
+// golang.org/x/time/rate. RoundTrip blocks on limiter.Wait(req.Context())
+// before delegating, so a canceled context unblocks it immediately.
+func WithRateLimit(rps float64, burst int) Option {
+	limiter := rate.NewLimiter(rate.Limit(rps), burst)
+
+	return WithLayer(func(base http.RoundTripper) http.RoundTripper {
+		return &RateLimitLayer{base: base, limiter: limiter}
+	})
+}
+
+// RateLimitLayer is the http.RoundTripper backing WithRateLimit.
+type RateLimitLayer struct {
+	base    http.RoundTripper
+	limiter *rate.Limiter
+}
+
+func (l *RateLimitLayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := l.limiter.Wait(req.Context()); err != nil {
+		return nil, err
+	}
+
+	return l.base.RoundTrip(req)
+}
@@ -0,0 +1,51 @@
+package httpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithRateLimit(t *testing.T) {
+	t.Run("spaces out requests beyond the burst", func(t *testing.T) {
+		s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+			rw.WriteHeader(http.StatusOK)
+		}))
+		defer s.Close()
+		client := New(WithRateLimit(10, 1))
+		req, err := http.NewRequest(http.MethodGet, s.URL, nil)
+		require.NoError(t, err)
+
+		start := time.Now()
+		_, err = client.DoReq(req)
+		require.NoError(t, err)
+		_, err = client.DoReq(req)
+		require.NoError(t, err)
+		elapsed := time.Since(start)
+
+		assert.GreaterOrEqual(t, elapsed, 90*time.Millisecond)
+	})
+
+	t.Run("respects context cancellation while waiting", func(t *testing.T) {
+		s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+			rw.WriteHeader(http.StatusOK)
+		}))
+		defer s.Close()
+		client := New(WithRateLimit(1, 1))
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+		require.NoError(t, err)
+
+		_, err = client.DoReq(req)
+		require.NoError(t, err)
+
+		_, err = client.DoReq(req)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,147 @@
+package httpc
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+)
+
+// ReqOption is an option to build an *http.Request, symmetric to RespOption
+// on the response side. Aborts if an option returns an error.
+type ReqOption func(c *Client, req *http.Request) error
+
+// Request builds an *http.Request against this client, applying reqOpts in
+// order. The result can be passed straight to DoReq or JSON.
+func (c *Client) Request(ctx context.Context, method, url string, reqOpts ...ReqOption) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, opt := range reqOpts {
+		if err := opt(c, req); err != nil {
+			if req.Body != nil {
+				// Unblock any writer goroutine (e.g. WithMultipart's pipe
+				// writer) that's blocked writing to a body we're about to
+				// discard.
+				_, _ = io.Copy(io.Discard, req.Body)
+				_ = req.Body.Close()
+			}
+			return nil, err
+		}
+	}
+
+	return req, nil
+}
+
+// DoReqOpts builds an *http.Request via Request and performs it via DoReq,
+// letting callers combine ReqOptions for the request with RespOptions for
+// the response in a single call.
+func (c *Client) DoReqOpts(ctx context.Context, method, url string, reqOpts []ReqOption, respOpts ...RespOption) (*http.Response, error) {
+	req, err := c.Request(ctx, method, url, reqOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.DoReq(req, respOpts...)
+}
+
+// JSONOpts builds an *http.Request via Request and performs it via JSON,
+// letting callers combine ReqOptions for the request with RespOptions for
+// the response in a single call.
+func (c *Client) JSONOpts(ctx context.Context, method, url string, reqOpts []ReqOption, obj any, respOpts ...RespOption) (*http.Response, error) {
+	req, err := c.Request(ctx, method, url, reqOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.JSON(req, obj, respOpts...)
+}
+
+// WithJSONBody marshals obj with Config.JsonMarshal, sets it as the request
+// body with a Content-Type: application/json header, and populates GetBody
+// so the request can be replayed by WithRetry.
+func WithJSONBody(obj any) ReqOption {
+	return func(c *Client, req *http.Request) error {
+		data, err := c.cfg.JsonMarshal(obj)
+		if err != nil {
+			return err
+		}
+
+		setRequestBody(req, data)
+		req.Header.Set("Content-Type", "application/json")
+
+		return nil
+	}
+}
+
+// WithFormBody encodes values as application/x-www-form-urlencoded and
+// sets it as the request body.
+func WithFormBody(values url.Values) ReqOption {
+	return func(_ *Client, req *http.Request) error {
+		setRequestBody(req, []byte(values.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		return nil
+	}
+}
+
+// WithMultipart streams a multipart/form-data body through an io.Pipe,
+// calling build to write parts via the given *multipart.Writer. The
+// request's Content-Type is set to the writer's boundary-qualified value.
+// Since the body is streamed rather than buffered, it cannot be replayed by
+// WithRetry.
+func WithMultipart(build func(w *multipart.Writer) error) ReqOption {
+	return func(_ *Client, req *http.Request) error {
+		pr, pw := io.Pipe()
+		mw := multipart.NewWriter(pw)
+
+		go func() {
+			err := build(mw)
+			if err == nil {
+				err = mw.Close()
+			}
+			_ = pw.CloseWithError(err)
+		}()
+
+		req.Body = pr
+		req.ContentLength = -1
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+
+		return nil
+	}
+}
+
+// WithQuery merges values into the request URL's query string.
+func WithQuery(values url.Values) ReqOption {
+	return func(_ *Client, req *http.Request) error {
+		q := req.URL.Query()
+		for k, vs := range values {
+			for _, v := range vs {
+				q.Add(k, v)
+			}
+		}
+		req.URL.RawQuery = q.Encode()
+
+		return nil
+	}
+}
+
+// WithBearerToken sets the Authorization header to "Bearer <token>".
+func WithBearerToken(token string) ReqOption {
+	return func(_ *Client, req *http.Request) error {
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	}
+}
+
+func setRequestBody(req *http.Request, data []byte) {
+	req.Body = io.NopCloser(bytes.NewReader(data))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	req.ContentLength = int64(len(data))
+}
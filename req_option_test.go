@@ -0,0 +1,177 @@
+package httpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Request(t *testing.T) {
+	t.Run("applies every ReqOption", func(t *testing.T) {
+		var gotHeader, gotQuery string
+		s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			gotHeader = req.Header.Get("Authorization")
+			gotQuery = req.URL.Query().Get("key")
+		}))
+		defer s.Close()
+		client := New()
+
+		req, err := client.Request(context.Background(), http.MethodGet, s.URL,
+			WithQuery(url.Values{"key": {"value"}}),
+			WithBearerToken("token123"),
+		)
+		require.NoError(t, err)
+		_, err = client.DoReq(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Bearer token123", gotHeader)
+		assert.Equal(t, "value", gotQuery)
+	})
+}
+
+func TestClient_DoReqOpts(t *testing.T) {
+	t.Run("builds and performs the request in one call", func(t *testing.T) {
+		var gotHeader string
+		s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			gotHeader = req.Header.Get("Authorization")
+			_, _ = rw.Write([]byte("ok"))
+		}))
+		defer s.Close()
+		client := New()
+
+		resp, err := client.DoReqOpts(context.Background(), http.MethodGet, s.URL, []ReqOption{WithBearerToken("token123")})
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "Bearer token123", gotHeader)
+	})
+}
+
+func TestClient_JSONOpts(t *testing.T) {
+	t.Run("builds, sends and decodes the response in one call", func(t *testing.T) {
+		s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			data, err := io.ReadAll(req.Body)
+			require.NoError(t, err)
+			_, _ = rw.Write(data)
+		}))
+		defer s.Close()
+		client := New()
+		var res TestStruct
+
+		_, err := client.JSONOpts(context.Background(), http.MethodPost, s.URL,
+			[]ReqOption{WithJSONBody(TestStruct{Name: "john"})}, &res)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "john", res.Name)
+	})
+}
+
+func TestWithJSONBody(t *testing.T) {
+	t.Run("marshals and sets content-type", func(t *testing.T) {
+		var gotBody, gotContentType string
+		s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			data, err := io.ReadAll(req.Body)
+			require.NoError(t, err)
+			gotBody = string(data)
+			gotContentType = req.Header.Get("Content-Type")
+		}))
+		defer s.Close()
+		client := New()
+
+		req, err := client.Request(context.Background(), http.MethodPost, s.URL, WithJSONBody(TestStruct{Name: "john"}))
+		require.NoError(t, err)
+		_, err = client.DoReq(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, `{"name":"john"}`, gotBody)
+		assert.Equal(t, "application/json", gotContentType)
+		assert.NotNil(t, req.GetBody)
+	})
+}
+
+func TestWithFormBody(t *testing.T) {
+	t.Run("encodes form values", func(t *testing.T) {
+		var gotBody string
+		s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			data, err := io.ReadAll(req.Body)
+			require.NoError(t, err)
+			gotBody = string(data)
+		}))
+		defer s.Close()
+		client := New()
+
+		req, err := client.Request(context.Background(), http.MethodPost, s.URL, WithFormBody(url.Values{"a": {"1"}}))
+		require.NoError(t, err)
+		_, err = client.DoReq(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "a=1", gotBody)
+	})
+}
+
+func TestWithMultipart(t *testing.T) {
+	t.Run("streams a multipart body", func(t *testing.T) {
+		var gotField string
+		s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			require.NoError(t, req.ParseMultipartForm(1<<20))
+			gotField = req.FormValue("name")
+		}))
+		defer s.Close()
+		client := New()
+
+		req, err := client.Request(context.Background(), http.MethodPost, s.URL, WithMultipart(func(w *multipart.Writer) error {
+			return w.WriteField("name", "john")
+		}))
+		require.NoError(t, err)
+		_, err = client.DoReq(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "john", gotField)
+	})
+
+	t.Run("does not leak the writer goroutine when a later ReqOption fails", func(t *testing.T) {
+		before := runtime.NumGoroutine()
+		client := New()
+		failErr := errors.New("boom")
+
+		_, err := client.Request(context.Background(), http.MethodPost, "http://example.invalid",
+			WithMultipart(func(w *multipart.Writer) error {
+				return w.WriteField("name", "john")
+			}),
+			func(_ *Client, _ *http.Request) error { return failErr },
+		)
+
+		assert.ErrorIs(t, err, failErr)
+		assert.Eventually(t, func() bool {
+			return runtime.NumGoroutine() <= before
+		}, time.Second, 10*time.Millisecond)
+	})
+}
+
+func TestWithBearerToken(t *testing.T) {
+	t.Run("sets authorization header", func(t *testing.T) {
+		var got string
+		s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			got = req.Header.Get("Authorization")
+		}))
+		defer s.Close()
+		client := New()
+
+		req, err := client.Request(context.Background(), http.MethodGet, s.URL, WithBearerToken("abc"))
+		require.NoError(t, err)
+		_, err = client.DoReq(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Bearer abc", got)
+	})
+}
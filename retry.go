@@ -0,0 +1,238 @@
+package httpc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// RetryDecision is the outcome of a RetryPolicy.Classifier call.
+type RetryDecision int
+
+const (
+	RetrySuccess RetryDecision = iota
+	RetryRetry
+	RetryAbort
+)
+
+// RetryPolicy configures the Layer installed by WithRetry.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	Jitter      float64
+
+	// Classifier decides, after each attempt, whether the request should be
+	// retried. Defaults to defaultRetryClassifier when nil.
+	Classifier func(req *http.Request, resp *http.Response, err error) RetryDecision
+
+	// Methods allowlists which request methods are eligible for retry.
+	// Defaults to GET/HEAD/PUT/DELETE/OPTIONS when nil.
+	Methods map[string]bool
+}
+
+var defaultRetryMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPut:     true,
+	http.MethodDelete:  true,
+	http.MethodOptions: true,
+}
+
+func defaultRetryClassifier(_ *http.Request, resp *http.Response, err error) RetryDecision {
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return RetryRetry
+		}
+		var netErr net.Error
+		if errors.As(err, &netErr) {
+			return RetryRetry
+		}
+		return RetryAbort
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return RetryRetry
+	default:
+		return RetrySuccess
+	}
+}
+
+// WithRetry installs a Layer that retries requests using the given
+// RetryPolicy. Only methods in policy.Methods are retried; the request body
+// is buffered via http.Request.GetBody (or an in-memory copy when the body
+// has a known ContentLength) so it can be replayed on each attempt.
+func WithRetry(policy RetryPolicy) Option {
+	if policy.Classifier == nil {
+		policy.Classifier = defaultRetryClassifier
+	}
+	if policy.Methods == nil {
+		policy.Methods = defaultRetryMethods
+	}
+
+	return WithLayer(func(base http.RoundTripper) http.RoundTripper {
+		return &RetryLayer{base: base, policy: policy}
+	})
+}
+
+// RetryLayer is the http.RoundTripper backing WithRetry.
+type RetryLayer struct {
+	base   http.RoundTripper
+	policy RetryPolicy
+}
+
+type retriesKey struct{}
+
+// RetriesFromContext returns the number of retry attempts made so far for
+// the in-flight request, as observed from a Layer further down the chain.
+func RetriesFromContext(ctx context.Context) int {
+	counter, ok := ctx.Value(retriesKey{}).(*atomic.Int32)
+	if !ok {
+		return 0
+	}
+	return int(counter.Load())
+}
+
+func (l *RetryLayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !l.policy.Methods[req.Method] {
+		return l.base.RoundTrip(req)
+	}
+
+	getBody, contentLength, err := replayableBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	maxAttempts := l.policy.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	if req.Body != nil && req.Body != http.NoBody && getBody == nil {
+		maxAttempts = 1
+	}
+
+	counter := &atomic.Int32{}
+	ctx := context.WithValue(req.Context(), retriesKey{}, counter)
+
+	var resp *http.Response
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		attemptReq := req.Clone(ctx)
+		if getBody != nil {
+			body, bErr := getBody()
+			if bErr != nil {
+				return nil, bErr
+			}
+			attemptReq.Body = body
+			attemptReq.ContentLength = contentLength
+		}
+
+		resp, err = l.base.RoundTrip(attemptReq)
+		if l.policy.Classifier(attemptReq, resp, err) != RetryRetry || attempt == maxAttempts {
+			return resp, err
+		}
+
+		delay := retryDelay(l.policy, attempt)
+		if resp != nil {
+			if after, ok := retryAfterDelay(resp); ok {
+				delay = after
+			}
+			_ = resp.Body.Close()
+		}
+
+		counter.Add(1)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return resp, ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	return resp, err
+}
+
+// replayableBody returns a function that produces a fresh copy of req's body
+// for each attempt, preferring req.GetBody and falling back to buffering the
+// body in memory when ContentLength is known. Returns a nil function when
+// req has no body or the body cannot be safely replayed.
+func replayableBody(req *http.Request) (func() (io.ReadCloser, error), int64, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, 0, nil
+	}
+	if req.GetBody != nil {
+		return req.GetBody, req.ContentLength, nil
+	}
+	if req.ContentLength < 0 {
+		return nil, 0, nil
+	}
+
+	data, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}, int64(len(data)), nil
+}
+
+func retryDelay(policy RetryPolicy, attempt int) time.Duration {
+	base := policy.BaseDelay
+	if base <= 0 {
+		base = 100 * time.Millisecond
+	}
+	max := policy.MaxDelay
+	if max <= 0 {
+		max = 30 * time.Second
+	}
+
+	delay := time.Duration(float64(base) * math.Pow(2, float64(attempt-1)))
+	if delay <= 0 || delay > max {
+		delay = max
+	}
+
+	if policy.Jitter > 0 {
+		factor := 1 + rand.Float64()*policy.Jitter - policy.Jitter/2
+		delay = time.Duration(float64(delay) * factor)
+	}
+	if delay < 0 {
+		delay = 0
+	}
+
+	return delay
+}
+
+// retryAfterDelay parses a Retry-After header, supporting both the
+// delay-seconds and HTTP-date forms.
+func retryAfterDelay(resp *http.Response) (time.Duration, bool) {
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(secs) * time.Second, true
+	}
+
+	if t, err := http.ParseTime(v); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,113 @@
+package httpc
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithRetry(t *testing.T) {
+	t.Run("retries on 503 then succeeds", func(t *testing.T) {
+		var attempts int32
+		s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+			if atomic.AddInt32(&attempts, 1) < 3 {
+				rw.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			rw.WriteHeader(http.StatusOK)
+		}))
+		defer s.Close()
+		client := New(WithRetry(RetryPolicy{
+			MaxAttempts: 5,
+			BaseDelay:   time.Millisecond,
+			MaxDelay:    5 * time.Millisecond,
+		}))
+		req, err := http.NewRequest(http.MethodGet, s.URL, nil)
+		require.NoError(t, err)
+
+		resp, err := client.DoReq(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.EqualValues(t, 3, atomic.LoadInt32(&attempts))
+	})
+
+	t.Run("gives up after MaxAttempts", func(t *testing.T) {
+		var attempts int32
+		s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+			atomic.AddInt32(&attempts, 1)
+			rw.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer s.Close()
+		client := New(WithRetry(RetryPolicy{
+			MaxAttempts: 2,
+			BaseDelay:   time.Millisecond,
+			MaxDelay:    5 * time.Millisecond,
+		}))
+		req, err := http.NewRequest(http.MethodGet, s.URL, nil)
+		require.NoError(t, err)
+
+		resp, err := client.DoReq(req)
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.EqualValues(t, 2, atomic.LoadInt32(&attempts))
+	})
+
+	t.Run("does not retry non-allowlisted methods", func(t *testing.T) {
+		var attempts int32
+		s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+			atomic.AddInt32(&attempts, 1)
+			rw.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer s.Close()
+		client := New(WithRetry(RetryPolicy{
+			MaxAttempts: 5,
+			BaseDelay:   time.Millisecond,
+		}))
+		req, err := http.NewRequest(http.MethodPost, s.URL, strings.NewReader("body"))
+		require.NoError(t, err)
+
+		_, err = client.DoReq(req)
+
+		assert.Error(t, err)
+		assert.EqualValues(t, 1, atomic.LoadInt32(&attempts))
+	})
+
+	t.Run("replays request body across attempts", func(t *testing.T) {
+		var attempts int32
+		var gotBodies []string
+		s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			data, err := io.ReadAll(req.Body)
+			require.NoError(t, err)
+			gotBodies = append(gotBodies, string(data))
+			if atomic.AddInt32(&attempts, 1) < 2 {
+				rw.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			rw.WriteHeader(http.StatusOK)
+		}))
+		defer s.Close()
+		client := New(WithRetry(RetryPolicy{
+			MaxAttempts: 3,
+			BaseDelay:   time.Millisecond,
+			Methods:     map[string]bool{http.MethodPut: true},
+		}))
+		req, err := http.NewRequest(http.MethodPut, s.URL, bytes.NewReader([]byte("payload")))
+		require.NoError(t, err)
+
+		resp, err := client.DoReq(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, []string{"payload", "payload"}, gotBodies)
+	})
+}
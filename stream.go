@@ -0,0 +1,192 @@
+package httpc
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event is a single Server-Sent Events message as dispatched by Client.SSE.
+type Event struct {
+	ID    string
+	Event string
+	Data  string
+}
+
+const defaultSSERetry = 3 * time.Second
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// SSE performs req and streams the response as Server-Sent Events per the
+// W3C EventSource spec, invoking handler for each dispatched Event. It
+// reconnects automatically both when the stream ends and when establishing
+// the connection itself fails (network errors, DNS, etc.), sending the
+// last received event id back via Last-Event-ID and waiting the
+// server-advertised retry delay (defaulting to 3s) beforehand. It stops and
+// returns the error from handler, from a non-2xx response via the
+// configured ErrorHandler, or from req.Context() being done.
+func (c *Client) SSE(req *http.Request, handler func(Event) error) error {
+	state := &sseState{retry: defaultSSERetry}
+
+	for {
+		attempt := req.Clone(req.Context())
+		if state.lastID != "" {
+			attempt.Header.Set("Last-Event-ID", state.lastID)
+		}
+
+		resp, doErr := c.Do(attempt)
+		if doErr == nil {
+			if err := c.consumeSSE(resp, handler, state); err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-req.Context().Done():
+			return req.Context().Err()
+		case <-time.After(state.retry):
+		}
+	}
+}
+
+type sseState struct {
+	lastID string
+	retry  time.Duration
+}
+
+func (c *Client) consumeSSE(resp *http.Response, handler func(Event) error, state *sseState) error {
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return c.cfg.errorHandler(c, resp, body)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	stripBOM(reader)
+
+	var evt Event
+	var data strings.Builder
+	idSet, dataSet := false, false
+
+	dispatch := func() error {
+		if idSet {
+			state.lastID = evt.ID
+		}
+		if !dataSet {
+			evt = Event{}
+			idSet, dataSet = false, false
+			return nil
+		}
+		evt.Data = strings.TrimSuffix(data.String(), "\n")
+		err := handler(evt)
+		evt = Event{}
+		data.Reset()
+		idSet, dataSet = false, false
+		return err
+	}
+
+	for {
+		line, err := readSSELine(reader)
+		if err != nil {
+			// The stream ended, cleanly or not: let SSE reconnect (or
+			// return the context's error if that is why the read failed).
+			return nil
+		}
+
+		if line == "" {
+			if err := dispatch(); err != nil {
+				return err
+			}
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+
+		switch field {
+		case "":
+			// comment line, ignore
+		case "event":
+			evt.Event = value
+		case "data":
+			data.WriteString(value)
+			data.WriteByte('\n')
+			dataSet = true
+		case "id":
+			evt.ID = value
+			idSet = true
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil {
+				state.retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+}
+
+func stripBOM(r *bufio.Reader) {
+	bom, err := r.Peek(len(utf8BOM))
+	if err == nil && bytes.Equal(bom, utf8BOM) {
+		_, _ = r.Discard(len(utf8BOM))
+	}
+}
+
+// readSSELine reads a single line with its trailing "\n" or "\r\n"
+// stripped. A blank result marks the end of an event.
+func readSSELine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		if err == io.EOF && line != "" {
+			return strings.TrimRight(line, "\r\n"), nil
+		}
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// NDJSON performs req and decodes the response body as newline-delimited
+// JSON using Config.JsonUnmarshal. factory is called for each line to
+// produce the value to decode into, which is then passed to handler.
+func (c *Client) NDJSON(req *http.Request, factory func() any, handler func(any) error) error {
+	resp, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(resp.Body)
+		return c.cfg.errorHandler(c, resp, body)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		select {
+		case <-req.Context().Done():
+			return req.Context().Err()
+		default:
+		}
+
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		obj := factory()
+		if err := c.cfg.JsonUnmarshal(line, obj); err != nil {
+			return err
+		}
+		if err := handler(obj); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
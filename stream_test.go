@@ -0,0 +1,172 @@
+package httpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_SSE(t *testing.T) {
+	t.Run("dispatches events and stops on handler error", func(t *testing.T) {
+		s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+			flusher := rw.(http.Flusher)
+			_, _ = fmt.Fprint(rw, "event: greeting\nid: 1\ndata: hello\ndata: world\n\n")
+			flusher.Flush()
+			_, _ = fmt.Fprint(rw, "data: second\n\n")
+			flusher.Flush()
+		}))
+		defer s.Close()
+		client := New()
+		req, err := http.NewRequest(http.MethodGet, s.URL, nil)
+		require.NoError(t, err)
+		var got []Event
+		stop := errors.New("stop")
+
+		err = client.SSE(req, func(e Event) error {
+			got = append(got, e)
+			if len(got) == 2 {
+				return stop
+			}
+			return nil
+		})
+
+		assert.ErrorIs(t, err, stop)
+		require.Len(t, got, 2)
+		assert.Equal(t, "greeting", got[0].Event)
+		assert.Equal(t, "1", got[0].ID)
+		assert.Equal(t, "hello\nworld", got[0].Data)
+		assert.Equal(t, "second", got[1].Data)
+	})
+
+	t.Run("propagates error status via error handler", func(t *testing.T) {
+		s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+			rw.WriteHeader(http.StatusBadRequest)
+			_, _ = rw.Write([]byte("bad request"))
+		}))
+		defer s.Close()
+		client := New()
+		req, err := http.NewRequest(http.MethodGet, s.URL, nil)
+		require.NoError(t, err)
+
+		err = client.SSE(req, func(Event) error { return nil })
+
+		assert.Error(t, err)
+	})
+
+	t.Run("waits to reconnect instead of returning immediately when Do fails", func(t *testing.T) {
+		var attempts int32
+		client := New(WithLayer(func(base http.RoundTripper) http.RoundTripper {
+			return LayerFn(func(_ *http.Request) (*http.Response, error) {
+				atomic.AddInt32(&attempts, 1)
+				return nil, errors.New("connection refused")
+			})
+		}))
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://example.invalid", nil)
+		require.NoError(t, err)
+
+		err = client.SSE(req, func(Event) error { return nil })
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.GreaterOrEqual(t, atomic.LoadInt32(&attempts), int32(1))
+	})
+
+	t.Run("an empty id field clears Last-Event-ID on reconnect", func(t *testing.T) {
+		var gotLastEventID []string
+		var attempts int32
+		s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			gotLastEventID = append(gotLastEventID, req.Header.Get("Last-Event-ID"))
+			flusher := rw.(http.Flusher)
+			if atomic.AddInt32(&attempts, 1) == 1 {
+				_, _ = fmt.Fprint(rw, "id: 1\ndata: first\nretry: 0\n\nid:\ndata: second\nretry: 0\n\n")
+				flusher.Flush()
+				return
+			}
+			_, _ = fmt.Fprint(rw, "data: third\n\n")
+			flusher.Flush()
+		}))
+		defer s.Close()
+		client := New()
+		req, err := http.NewRequest(http.MethodGet, s.URL, nil)
+		require.NoError(t, err)
+		var got []Event
+		stop := errors.New("stop")
+
+		err = client.SSE(req, func(e Event) error {
+			got = append(got, e)
+			if len(got) == 3 {
+				return stop
+			}
+			return nil
+		})
+
+		assert.ErrorIs(t, err, stop)
+		require.Len(t, gotLastEventID, 2)
+		assert.Equal(t, "", gotLastEventID[0])
+		assert.Equal(t, "", gotLastEventID[1])
+	})
+
+	t.Run("a keep-alive block with only an id field updates Last-Event-ID without dispatching", func(t *testing.T) {
+		var gotLastEventID []string
+		var attempts int32
+		s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			gotLastEventID = append(gotLastEventID, req.Header.Get("Last-Event-ID"))
+			flusher := rw.(http.Flusher)
+			if atomic.AddInt32(&attempts, 1) == 1 {
+				_, _ = fmt.Fprint(rw, "id: 5\nretry: 0\n\n")
+				flusher.Flush()
+				return
+			}
+			_, _ = fmt.Fprint(rw, "data: hello\n\n")
+			flusher.Flush()
+		}))
+		defer s.Close()
+		client := New()
+		req, err := http.NewRequest(http.MethodGet, s.URL, nil)
+		require.NoError(t, err)
+		var got []Event
+		stop := errors.New("stop")
+
+		err = client.SSE(req, func(e Event) error {
+			got = append(got, e)
+			return stop
+		})
+
+		assert.ErrorIs(t, err, stop)
+		require.Len(t, got, 1)
+		assert.Equal(t, "hello", got[0].Data)
+		require.Len(t, gotLastEventID, 2)
+		assert.Equal(t, "", gotLastEventID[0])
+		assert.Equal(t, "5", gotLastEventID[1])
+	})
+}
+
+func TestClient_NDJSON(t *testing.T) {
+	t.Run("decodes each line", func(t *testing.T) {
+		s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+			_, _ = fmt.Fprint(rw, "{\"name\":\"a\"}\n{\"name\":\"b\"}\n")
+		}))
+		defer s.Close()
+		client := New()
+		req, err := http.NewRequest(http.MethodGet, s.URL, nil)
+		require.NoError(t, err)
+		var names []string
+
+		err = client.NDJSON(req, func() any { return &TestStruct{} }, func(v any) error {
+			names = append(names, v.(*TestStruct).Name)
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"a", "b"}, names)
+	})
+}
@@ -0,0 +1,122 @@
+package httpc
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"time"
+)
+
+// WithClientTrace installs a TraceLayer that wraps each outgoing request in
+// an httptrace.ClientTrace produced by fn.
+func WithClientTrace(fn func(req *http.Request) *httptrace.ClientTrace) Option {
+	return WithLayer(func(base http.RoundTripper) http.RoundTripper {
+		return &TraceLayer{base: base, fn: fn}
+	})
+}
+
+// TraceLayer is the http.RoundTripper backing WithClientTrace.
+type TraceLayer struct {
+	base http.RoundTripper
+	fn   func(req *http.Request) *httptrace.ClientTrace
+}
+
+func (l *TraceLayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	trace := l.fn(req)
+	if trace == nil {
+		return l.base.RoundTrip(req)
+	}
+
+	ctx := httptrace.WithClientTrace(req.Context(), trace)
+	return l.base.RoundTrip(req.WithContext(ctx))
+}
+
+// RequestTrace holds the timing breakdown of a single request as gathered
+// by WithMetrics.
+type RequestTrace struct {
+	DNS        time.Duration
+	Connect    time.Duration
+	TLS        time.Duration
+	TTFB       time.Duration
+	Total      time.Duration
+	GotConn    bool
+	ConnReused bool
+}
+
+// MetricsSink receives a RequestTrace for every request observed by
+// WithMetrics, along with the request/response pair and any transport
+// error, so it can be fed into Prometheus, OTel, or any other backend.
+type MetricsSink interface {
+	Observe(req *http.Request, resp *http.Response, trace RequestTrace, err error)
+}
+
+// MetricsSinkFunc adapts a function to a MetricsSink.
+type MetricsSinkFunc func(req *http.Request, resp *http.Response, trace RequestTrace, err error)
+
+func (f MetricsSinkFunc) Observe(req *http.Request, resp *http.Response, trace RequestTrace, err error) {
+	f(req, resp, trace, err)
+}
+
+// WithMetrics installs a Layer whose httptrace hooks populate a
+// RequestTrace and hand it to sink.Observe once the request completes.
+func WithMetrics(sink MetricsSink) Option {
+	return WithLayer(func(base http.RoundTripper) http.RoundTripper {
+		return &metricsLayer{base: base, sink: sink}
+	})
+}
+
+type metricsLayer struct {
+	base http.RoundTripper
+	sink MetricsSink
+}
+
+func (l *metricsLayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	var (
+		dnsStart, connectStart, tlsStart time.Time
+		trace                            RequestTrace
+	)
+
+	start := time.Now()
+	ct := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			trace.GotConn = true
+			trace.ConnReused = info.Reused
+		},
+		DNSStart: func(httptrace.DNSStartInfo) {
+			dnsStart = time.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			if !dnsStart.IsZero() {
+				trace.DNS = time.Since(dnsStart)
+			}
+		},
+		ConnectStart: func(string, string) {
+			connectStart = time.Now()
+		},
+		ConnectDone: func(string, string, error) {
+			if !connectStart.IsZero() {
+				trace.Connect = time.Since(connectStart)
+			}
+		},
+		TLSHandshakeStart: func() {
+			tlsStart = time.Now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			if !tlsStart.IsZero() {
+				trace.TLS = time.Since(tlsStart)
+			}
+		},
+		GotFirstResponseByte: func() {
+			trace.TTFB = time.Since(start)
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {},
+	}
+
+	ctx := httptrace.WithClientTrace(req.Context(), ct)
+	resp, err := l.base.RoundTrip(req.WithContext(ctx))
+	trace.Total = time.Since(start)
+
+	l.sink.Observe(req, resp, trace, err)
+
+	return resp, err
+}
@@ -0,0 +1,64 @@
+package httpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithClientTrace(t *testing.T) {
+	t.Run("invokes the provided trace", func(t *testing.T) {
+		s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+			rw.WriteHeader(http.StatusOK)
+		}))
+		defer s.Close()
+		var gotConn bool
+		client := New(WithClientTrace(func(_ *http.Request) *httptrace.ClientTrace {
+			return &httptrace.ClientTrace{
+				GotConn: func(httptrace.GotConnInfo) {
+					gotConn = true
+				},
+			}
+		}))
+		req, err := http.NewRequest(http.MethodGet, s.URL, nil)
+		require.NoError(t, err)
+
+		_, err = client.DoReq(req)
+
+		assert.NoError(t, err)
+		assert.True(t, gotConn)
+	})
+}
+
+type recordingSink struct {
+	traces []RequestTrace
+}
+
+func (r *recordingSink) Observe(_ *http.Request, _ *http.Response, trace RequestTrace, _ error) {
+	r.traces = append(r.traces, trace)
+}
+
+func TestWithMetrics(t *testing.T) {
+	t.Run("records a RequestTrace per request", func(t *testing.T) {
+		s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+			rw.WriteHeader(http.StatusOK)
+		}))
+		defer s.Close()
+		sink := &recordingSink{}
+		client := New(WithMetrics(sink))
+		req, err := http.NewRequest(http.MethodGet, s.URL, nil)
+		require.NoError(t, err)
+
+		_, err = client.DoReq(req)
+
+		assert.NoError(t, err)
+		require.Len(t, sink.traces, 1)
+		assert.True(t, sink.traces[0].GotConn)
+		assert.Greater(t, sink.traces[0].Total, time.Duration(0))
+	})
+}